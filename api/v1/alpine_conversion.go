@@ -0,0 +1,25 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+// AlpineConversionDataAnnotation stores fields that exist on this, the
+// storage version, but have no home on a spoke version, so that converting
+// hub -> spoke -> hub does not lose them.
+const AlpineConversionDataAnnotation = "staight.k8s.io/conversion-data"
+
+// Hub marks this version of Alpine as the conversion hub. All other
+// versions implement ConvertTo/ConvertFrom against this type.
+func (*Alpine) Hub() {}
@@ -28,6 +28,46 @@ type AlpineSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 	PodTemplate corev1.PodTemplateSpec `json:"podTemplate,omitempty"`
+
+	// Replicas is the desired number of pods the Alpine should keep running.
+	// Defaults to 1 when unset.
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Completions is the desired number of successfully completed pods. When
+	// set, the Alpine behaves like a Job: pods that succeed count toward
+	// Completions and are not recreated.
+	// +optional
+	Completions *int32 `json:"completions,omitempty"`
+
+	// Parallelism caps how many pods may be running at once while working
+	// toward Completions. Defaults to Replicas when unset.
+	// +optional
+	Parallelism *int32 `json:"parallelism,omitempty"`
+
+	// MinMember is the minimum number of pods that must be scheduled
+	// together before the scheduler admits any of them. When set, the
+	// Alpine controller maintains a sibling PodGroup to enable gang
+	// scheduling.
+	// +optional
+	MinMember *int32 `json:"minMember,omitempty"`
+
+	// SchedulerName is the scheduler that should place the Alpine's pods.
+	// Required to take advantage of MinMember, since gang scheduling is
+	// only honored by a gang-aware scheduler.
+	// +optional
+	SchedulerName string `json:"schedulerName,omitempty"`
+
+	// Quotas, when set, causes the controller to maintain a sibling
+	// ResourceQuota named after this Alpine and refuse to create more pods
+	// once this Alpine's active pods reach the quota's Hard pod count. Other
+	// Hard resources (cpu, memory, ...) are not checked before creating
+	// pods. ResourceQuota is a
+	// namespace-scoped API, so the quota's Hard limits apply to the whole
+	// namespace; Alpines sharing a namespace and both setting Quotas will
+	// contend for the same limits.
+	// +optional
+	Quotas corev1.ResourceQuotaSpec `json:"quotas,omitempty"`
 }
 
 // AlpineStatus defines the observed state of Alpine
@@ -35,10 +75,34 @@ type AlpineStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 	Active []corev1.ObjectReference `json:"active,omitempty"`
+
+	// Succeeded is the number of child pods that have completed successfully.
+	// +optional
+	Succeeded int32 `json:"succeeded,omitempty"`
+
+	// Failed is the number of child pods that have terminated in failure.
+	// +optional
+	Failed int32 `json:"failed,omitempty"`
+
+	// PodGroupPhase mirrors the phase of the sibling PodGroup used for gang
+	// scheduling, when Spec.MinMember is set.
+	// +optional
+	PodGroupPhase string `json:"podGroupPhase,omitempty"`
+
+	// Quotas mirrors the observed status of the sibling ResourceQuota, when
+	// Spec.Quotas is set.
+	// +optional
+	Quotas corev1.ResourceQuotaStatus `json:"quotas,omitempty"`
+
+	// Conditions represent the latest available observations of the
+	// Alpine's state.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:storageversion
 
 // Alpine is the Schema for the alpines API
 type Alpine struct {
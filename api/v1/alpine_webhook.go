@@ -0,0 +1,128 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// alpinelog is for logging in this package.
+var alpinelog = logf.Log.WithName("alpine-webhook")
+
+// defaultImage and defaultCommand are used to fill in the default container
+// when an Alpine is created without a PodTemplate.
+var (
+	defaultImage    = "alpine:latest"
+	defaultCommand  = []string{"sleep", "3600"}
+	defaultReplicas = int32(1)
+)
+
+func (r *Alpine) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithDefaulter(r).
+		WithValidator(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-staight-k8s-io-v1-alpine,mutating=true,failurePolicy=fail,sideEffects=None,groups=staight.k8s.io,resources=alpines,verbs=create;update,versions=v1,name=malpine.kb.io,admissionReviewVersions=v1
+
+// Default implements admission.CustomDefaulter so a webhook will be
+// registered for the type.
+func (r *Alpine) Default(_ context.Context, obj runtime.Object) error {
+	alpine, ok := obj.(*Alpine)
+	if !ok {
+		return fmt.Errorf("expected an Alpine but got a %T", obj)
+	}
+	alpinelog.Info("default", "name", alpine.Name)
+
+	if len(alpine.Spec.PodTemplate.Spec.Containers) == 0 {
+		alpine.Spec.PodTemplate.Spec.Containers = []corev1.Container{{
+			Name:    "alpine",
+			Image:   defaultImage,
+			Command: append([]string{}, defaultCommand...),
+		}}
+	}
+	if alpine.Spec.Replicas == nil {
+		alpine.Spec.Replicas = &defaultReplicas
+	}
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-staight-k8s-io-v1-alpine,mutating=false,failurePolicy=fail,sideEffects=None,groups=staight.k8s.io,resources=alpines,verbs=create;update,versions=v1,name=valpine.kb.io,admissionReviewVersions=v1
+
+// ValidateCreate implements admission.CustomValidator.
+func (r *Alpine) ValidateCreate(_ context.Context, obj runtime.Object) error {
+	alpine, ok := obj.(*Alpine)
+	if !ok {
+		return fmt.Errorf("expected an Alpine but got a %T", obj)
+	}
+	alpinelog.Info("validate create", "name", alpine.Name)
+	return alpine.validate()
+}
+
+// ValidateUpdate implements admission.CustomValidator.
+func (r *Alpine) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) error {
+	oldAlpine, ok := oldObj.(*Alpine)
+	if !ok {
+		return fmt.Errorf("expected an Alpine but got a %T", oldObj)
+	}
+	newAlpine, ok := newObj.(*Alpine)
+	if !ok {
+		return fmt.Errorf("expected an Alpine but got a %T", newObj)
+	}
+	alpinelog.Info("validate update", "name", newAlpine.Name)
+
+	if err := newAlpine.validate(); err != nil {
+		return err
+	}
+
+	oldContainers := oldAlpine.Spec.PodTemplate.Spec.Containers
+	newContainers := newAlpine.Spec.PodTemplate.Spec.Containers
+	for i := range oldContainers {
+		if i >= len(newContainers) {
+			break
+		}
+		if oldContainers[i].Name != newContainers[i].Name {
+			return fmt.Errorf("spec.podTemplate.spec.containers[%d].name is immutable", i)
+		}
+	}
+	return nil
+}
+
+// ValidateDelete implements admission.CustomValidator.
+func (r *Alpine) ValidateDelete(_ context.Context, obj runtime.Object) error {
+	return nil
+}
+
+// validate runs the checks shared by create and update.
+func (r *Alpine) validate() error {
+	for i, c := range r.Spec.PodTemplate.Spec.Containers {
+		if c.Name == "" && c.Image != "" {
+			return fmt.Errorf("spec.podTemplate.spec.containers[%d] sets an image but no name", i)
+		}
+	}
+	if r.Spec.Replicas != nil && *r.Spec.Replicas < 0 {
+		return fmt.Errorf("spec.replicas must not be negative")
+	}
+	return nil
+}
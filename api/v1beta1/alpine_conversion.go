@@ -0,0 +1,94 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"reflect"
+
+	v1 "crd/api/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// conversionData carries the fields v1 has that v1beta1, a spoke version,
+// does not, so converting v1 -> v1beta1 -> v1 round-trips losslessly.
+type conversionData struct {
+	QuotasSpec   corev1.ResourceQuotaSpec   `json:"quotasSpec,omitempty"`
+	QuotasStatus corev1.ResourceQuotaStatus `json:"quotasStatus,omitempty"`
+}
+
+// ConvertTo converts this Alpine (v1beta1) to the Hub version (v1).
+func (src *Alpine) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1.Alpine)
+
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+	dst.Spec.PodTemplate = src.Spec.Template
+	dst.Spec.Replicas = src.Spec.Replicas
+	dst.Spec.Completions = src.Spec.Completions
+	dst.Spec.Parallelism = src.Spec.Parallelism
+	dst.Spec.MinMember = src.Spec.MinMember
+	dst.Spec.SchedulerName = src.Spec.SchedulerName
+
+	dst.Status.Active = src.Status.Active
+	dst.Status.Succeeded = src.Status.Succeeded
+	dst.Status.Failed = src.Status.Failed
+	dst.Status.PodGroupPhase = src.Status.PodGroupPhase
+	dst.Status.Conditions = src.Status.Conditions
+
+	if raw, ok := src.Annotations[v1.AlpineConversionDataAnnotation]; ok {
+		var data conversionData
+		if err := json.Unmarshal([]byte(raw), &data); err != nil {
+			return err
+		}
+		dst.Spec.Quotas = data.QuotasSpec
+		dst.Status.Quotas = data.QuotasStatus
+		delete(dst.Annotations, v1.AlpineConversionDataAnnotation)
+	}
+	return nil
+}
+
+// ConvertFrom converts the Hub version (v1) to this Alpine (v1beta1).
+func (dst *Alpine) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1.Alpine)
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec.Template = src.Spec.PodTemplate
+	dst.Spec.Replicas = src.Spec.Replicas
+	dst.Spec.Completions = src.Spec.Completions
+	dst.Spec.Parallelism = src.Spec.Parallelism
+	dst.Spec.MinMember = src.Spec.MinMember
+	dst.Spec.SchedulerName = src.Spec.SchedulerName
+
+	dst.Status.Active = src.Status.Active
+	dst.Status.Succeeded = src.Status.Succeeded
+	dst.Status.Failed = src.Status.Failed
+	dst.Status.PodGroupPhase = src.Status.PodGroupPhase
+	dst.Status.Conditions = src.Status.Conditions
+
+	if !reflect.DeepEqual(src.Spec.Quotas, corev1.ResourceQuotaSpec{}) || !reflect.DeepEqual(src.Status.Quotas, corev1.ResourceQuotaStatus{}) {
+		data, err := json.Marshal(conversionData{QuotasSpec: src.Spec.Quotas, QuotasStatus: src.Status.Quotas})
+		if err != nil {
+			return err
+		}
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[v1.AlpineConversionDataAnnotation] = string(data)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AlpineSpec defines the desired state of Alpine
+type AlpineSpec struct {
+	// Template is the pod template used to create the Alpine's child pods.
+	Template corev1.PodTemplateSpec `json:"template,omitempty"`
+
+	// Replicas is the desired number of pods the Alpine should keep running.
+	// Defaults to 1 when unset.
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Completions is the desired number of successfully completed pods.
+	// +optional
+	Completions *int32 `json:"completions,omitempty"`
+
+	// Parallelism caps how many pods may be running at once while working
+	// toward Completions. Defaults to Replicas when unset.
+	// +optional
+	Parallelism *int32 `json:"parallelism,omitempty"`
+
+	// MinMember is the minimum number of pods that must be scheduled
+	// together before the scheduler admits any of them.
+	// +optional
+	MinMember *int32 `json:"minMember,omitempty"`
+
+	// SchedulerName is the scheduler that should place the Alpine's pods.
+	// +optional
+	SchedulerName string `json:"schedulerName,omitempty"`
+}
+
+// AlpineStatus defines the observed state of Alpine
+type AlpineStatus struct {
+	// Active is the list of child pods that have neither succeeded nor
+	// failed, pending pods included.
+	// +optional
+	Active []corev1.ObjectReference `json:"active,omitempty"`
+
+	// Succeeded is the number of child pods that have completed successfully.
+	// +optional
+	Succeeded int32 `json:"succeeded,omitempty"`
+
+	// Failed is the number of child pods that have terminated in failure.
+	// +optional
+	Failed int32 `json:"failed,omitempty"`
+
+	// PodGroupPhase mirrors the phase of the sibling PodGroup used for gang
+	// scheduling, when Spec.MinMember is set.
+	// +optional
+	PodGroupPhase string `json:"podGroupPhase,omitempty"`
+
+	// Conditions represent the latest available observations of the
+	// Alpine's state.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// Alpine is the Schema for the alpines API
+type Alpine struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AlpineSpec   `json:"spec,omitempty"`
+	Status AlpineStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AlpineList contains a list of Alpine
+type AlpineList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Alpine `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Alpine{}, &AlpineList{})
+}
@@ -21,15 +21,24 @@ import (
 	"fmt"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	ref "k8s.io/client-go/tools/reference"
 	"reflect"
+	"sort"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"time"
 )
 
+const statusFieldManager = "alpine-controller"
+
 var (
 	podOwnerKey = ".metadata.controller"
 	apiGVstr    = staightv1.GroupVersion.String()
@@ -43,13 +52,21 @@ var (
 		RestartPolicy: corev1.RestartPolicyAlways,
 	}
 	scheduledTimeAnnotation = "staight.k8s.io/scheduled-at"
+	podGroupLabel           = "scheduling.k8s.io/pod-group"
+	alpineNameLabel         = "staight.k8s.io/alpine"
+	podGroupGVK             = schema.GroupVersionKind{
+		Group:   "scheduling.sigs.k8s.io",
+		Version: "v1alpha1",
+		Kind:    "PodGroup",
+	}
 )
 
 // AlpineReconciler reconciles a Alpine object
 type AlpineReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 }
 
 func ignoreNotFound(err error) error {
@@ -61,9 +78,11 @@ func ignoreNotFound(err error) error {
 
 // +kubebuilder:rbac:groups=staight.k8s.io,resources=alpines,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=staight.k8s.io,resources=alpines/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=resourcequotas,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
-func (r *AlpineReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
-	ctx := context.Background()
+func (r *AlpineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := r.Log.WithValues("alpine", req.NamespacedName)
 
 	// your logic here
@@ -73,34 +92,58 @@ func (r *AlpineReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		log.Error(err, "unable to fetch alpine")
 		return ctrl.Result{}, ignoreNotFound(err)
 	}
+	observedStatus := alpine.Status.DeepCopy()
 
 	// 列出所有控制的pod
 	var childPods corev1.PodList
-	if err := r.List(ctx, &childPods, client.InNamespace(req.Namespace), client.MatchingField(podOwnerKey, req.Name)); err != nil {
+	if err := r.List(ctx, &childPods, client.InNamespace(req.Namespace), client.MatchingFields{podOwnerKey: req.Name}); err != nil {
 		log.Error(err, "unable to list child pods")
 		return ctrl.Result{}, err
 	}
 
-	// 获取控制pod的数量
-	size := len(childPods.Items)
-	log.V(1).Info("pod count", "active pod", size)
+	// 按状态把子pod分类：活跃（未结束，含Pending）/成功/失败
+	var active []corev1.Pod
+	var succeeded, failed int32
+	for _, pod := range childPods.Items {
+		switch pod.Status.Phase {
+		case corev1.PodSucceeded:
+			succeeded++
+		case corev1.PodFailed:
+			failed++
+		default:
+			active = append(active, pod)
+		}
+	}
+	log.V(1).Info("pod count", "active", len(active), "succeeded", succeeded, "failed", failed)
 
-	// 如果数量不为0，则直接返回
-	if size != 0 {
-		log.V(1).Info("has child pod, skip")
-		return ctrl.Result{}, nil
+	// 期望的副本数，默认为1
+	replicas := int32(1)
+	if alpine.Spec.Replicas != nil {
+		replicas = *alpine.Spec.Replicas
 	}
 
-	// 更新alpine的状态
-	if err := r.Status().Update(ctx, &alpine); err != nil {
-		log.Error(err, "unable to update Alpine status")
-		return ctrl.Result{}, err
+	// 如果设置了Completions，则以剩余未完成的数量为目标，并受Parallelism限制
+	if alpine.Spec.Completions != nil {
+		remaining := *alpine.Spec.Completions - succeeded
+		if remaining < 0 {
+			remaining = 0
+		}
+		parallelism := replicas
+		if alpine.Spec.Parallelism != nil {
+			parallelism = *alpine.Spec.Parallelism
+		}
+		replicas = remaining
+		if replicas > parallelism {
+			replicas = parallelism
+		}
 	}
 
+	diff := int32(len(active)) - replicas
+
 	// 构造需要创建的pod：如果有pod模板，则使用pod模板创建；否则使用默认模板
 	constructPodForAlpine := func(alpine *staightv1.Alpine) (*corev1.Pod, error) {
 		scheduledTime := time.Now()
-		name := fmt.Sprintf("%s-%d", alpine.Name, scheduledTime.Unix())
+		generateName := fmt.Sprintf("%s-%d-", alpine.Name, scheduledTime.Unix())
 		spec := podSpec
 
 		// fmt.Printf("get alpine: %+v\n", alpine.Spec.PodTemplate.Spec)
@@ -115,10 +158,10 @@ func (r *AlpineReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		// 构造pod
 		pod := &corev1.Pod{
 			ObjectMeta: metav1.ObjectMeta{
-				Namespace:   alpine.Namespace,
-				Name:        name,
-				Labels:      make(map[string]string),
-				Annotations: make(map[string]string),
+				Namespace:    alpine.Namespace,
+				GenerateName: generateName,
+				Labels:       make(map[string]string),
+				Annotations:  make(map[string]string),
 			},
 			Spec: spec,
 		}
@@ -131,6 +174,13 @@ func (r *AlpineReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		for k, v := range alpine.Spec.PodTemplate.Labels {
 			pod.Labels[k] = v
 		}
+		pod.Labels[alpineNameLabel] = alpine.Name
+
+		// gang调度：给pod打上pod-group标签，供gang-aware调度器识别
+		if alpine.Spec.MinMember != nil {
+			pod.Labels[podGroupLabel] = alpine.Name
+			pod.Spec.SchedulerName = alpine.Spec.SchedulerName
+		}
 
 		// 设置控制关系，实际上是给pod添加了.metadata.ownerReferences字段
 		if err := ctrl.SetControllerReference(alpine, pod, r.Scheme); err != nil {
@@ -139,25 +189,238 @@ func (r *AlpineReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		return pod, nil
 	}
 
-	pod, err := constructPodForAlpine(&alpine)
-	if err != nil {
-		log.Error(err, "unable to construct pod from template")
+	var templateErr error
+	var podsCreated int32
+	quotaExceeded := false
+
+	// 配额：维护一个名为<Alpine名>-quota的ResourceQuota，本Alpine的active pod数达到Hard中的pods上限时不再创建新pod
+	// （Hard中的其它资源（如cpu、memory）在创建前不做检查）。
+	// 注意：ResourceQuota本身是namespace级别的资源，无法通过label selector
+	// 限定到某个Alpine的子pod，所以quotaStatus.Hard/Used反映的是整个namespace
+	// 的用量（同一namespace下多个设置了Spec.Quotas的Alpine会共享同一限制）。
+	// 为了让这里的创建门槛真正只针对本Alpine生效，用本Alpine自己的active pod
+	// 数量而不是namespace级别的Used来计算剩余配额。
+	var quotaRoom int32 = diff * -1
+	if !reflect.DeepEqual(alpine.Spec.Quotas, corev1.ResourceQuotaSpec{}) {
+		quotaStatus, err := r.ensureResourceQuota(ctx, &alpine)
+		if err != nil {
+			log.Error(err, "unable to ensure ResourceQuota for alpine")
+			return ctrl.Result{}, err
+		}
+		alpine.Status.Quotas = quotaStatus
+
+		if hardPods, ok := quotaStatus.Hard[corev1.ResourcePods]; ok {
+			room := hardPods.Value() - int64(len(active))
+			if room < 0 {
+				room = 0
+			}
+			if diff < 0 && int64(quotaRoom) > room {
+				log.V(1).Info("quota limits new pods for alpine", "room", room, "wanted", quotaRoom)
+				quotaRoom = int32(room)
+				quotaExceeded = true
+			}
+		}
+	}
+
+	switch {
+	case diff > 0:
+		// 副本数超出期望，删掉多余的pod（先删最近创建的，保留老pod）
+		sort.Slice(active, func(i, j int) bool {
+			return active[i].CreationTimestamp.After(active[j].CreationTimestamp.Time)
+		})
+		for i := int32(0); i < diff; i++ {
+			pod := &active[i]
+			if err := r.Delete(ctx, pod); err != nil && !apierrs.IsNotFound(err) {
+				log.Error(err, "unable to delete excess pod for alpine", "pod", pod)
+				return ctrl.Result{}, err
+			}
+			log.V(1).Info("deleted excess pod for alpine", "pod", pod.Name)
+		}
+		active = active[diff:]
+	case diff < 0:
+		// 副本数不足期望，补齐缺口，但不超过配额余量
+		for i := int32(0); i < -diff && i < quotaRoom; i++ {
+			pod, err := constructPodForAlpine(&alpine)
+			if err != nil {
+				log.Error(err, "unable to construct pod from template")
+				templateErr = err
+				break
+			}
+
+			if err := r.Create(ctx, pod); err != nil {
+				log.Error(err, "unable to create pod for alpine", "pod", pod)
+				return ctrl.Result{}, err
+			}
+
+			log.V(1).Info("create pod for alpine run", "pod", pod)
+			active = append(active, *pod)
+			podsCreated++
+		}
+	}
+
+	// gang调度：维护一个与Alpine同名的PodGroup
+	if alpine.Spec.MinMember != nil {
+		phase, err := r.ensurePodGroup(ctx, &alpine)
+		if err != nil {
+			log.Error(err, "unable to ensure PodGroup for alpine")
+			return ctrl.Result{}, err
+		}
+		alpine.Status.PodGroupPhase = phase
+	}
+
+	// 将活跃（未结束）的子pod写回status.Active
+	alpine.Status.Active = nil
+	for i := range active {
+		podRef, err := ref.GetReference(r.Scheme, &active[i])
+		if err != nil {
+			log.Error(err, "unable to make reference to active pod", "pod", active[i].Name)
+			continue
+		}
+		alpine.Status.Active = append(alpine.Status.Active, *podRef)
+	}
+	alpine.Status.Succeeded = succeeded
+	alpine.Status.Failed = failed
+
+	// 汇总出Ready condition：按TemplateInvalid > QuotaExceeded > PodsCreated > Ready的优先级
+	reason, condStatus, message := "Ready", metav1.ConditionTrue, "alpine has the desired number of active pods"
+	switch {
+	case templateErr != nil:
+		reason, condStatus, message = "TemplateInvalid", metav1.ConditionFalse, templateErr.Error()
+	case quotaExceeded:
+		reason, condStatus, message = "QuotaExceeded", metav1.ConditionFalse, "resource quota has no room for the desired replica count"
+	case podsCreated > 0:
+		reason, condStatus, message = "PodsCreated", metav1.ConditionTrue, fmt.Sprintf("created %d pod(s)", podsCreated)
+	case int32(len(active)) != replicas:
+		reason, condStatus, message = "PodsCreated", metav1.ConditionFalse, "waiting for active pods to reach the desired count"
+	}
+
+	prevReady := apimeta.FindStatusCondition(observedStatus.Conditions, "Ready")
+	apimeta.SetStatusCondition(&alpine.Status.Conditions, metav1.Condition{
+		Type:    "Ready",
+		Status:  condStatus,
+		Reason:  reason,
+		Message: message,
+	})
+	if r.Recorder != nil && (prevReady == nil || prevReady.Reason != reason || prevReady.Status != condStatus) {
+		eventType := corev1.EventTypeNormal
+		if condStatus == metav1.ConditionFalse {
+			eventType = corev1.EventTypeWarning
+		}
+		r.Recorder.Event(&alpine, eventType, reason, message)
+	}
+
+	// 只有计算出的状态和已观测到的状态不一致时才下发更新，避免update风暴
+	if equality.Semantic.DeepEqual(alpine.Status, *observedStatus) {
 		return ctrl.Result{}, nil
 	}
 
-	// 创建pod
-	if err := r.Create(ctx, pod); err != nil {
-		log.Error(err, "unable to create pod for alpine", "pod", pod)
+	// 用server-side apply更新status，FieldManager为alpine-controller，允许其它controller/webhook共同拥有不相交的字段
+	apply := &staightv1.Alpine{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: staightv1.GroupVersion.String(),
+			Kind:       "Alpine",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      alpine.Name,
+			Namespace: alpine.Namespace,
+		},
+		Status: alpine.Status,
+	}
+	if err := r.Status().Patch(ctx, apply, client.Apply, client.FieldOwner(statusFieldManager), client.ForceOwnership); err != nil {
+		log.Error(err, "unable to apply Alpine status")
 		return ctrl.Result{}, err
 	}
 
-	log.V(1).Info("create pod for alpine run", "pod", pod)
-
 	return ctrl.Result{}, nil
 }
 
+// ensurePodGroup creates or updates the sibling PodGroup used for gang
+// scheduling, recreating it if its spec has drifted, and returns the
+// PodGroup's observed phase.
+func (r *AlpineReconciler) ensurePodGroup(ctx context.Context, alpine *staightv1.Alpine) (string, error) {
+	desiredMinMember := *alpine.Spec.MinMember
+
+	var podGroup unstructured.Unstructured
+	podGroup.SetGroupVersionKind(podGroupGVK)
+	err := r.Get(ctx, client.ObjectKey{Namespace: alpine.Namespace, Name: alpine.Name}, &podGroup)
+	if apierrs.IsNotFound(err) {
+		return "", r.createPodGroup(ctx, alpine, desiredMinMember)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	minMember, _, _ := unstructured.NestedInt64(podGroup.Object, "spec", "minMember")
+	if int32(minMember) != desiredMinMember {
+		// spec有漂移，删除重建
+		if err := r.Delete(ctx, &podGroup); err != nil && !apierrs.IsNotFound(err) {
+			return "", err
+		}
+		return "", r.createPodGroup(ctx, alpine, desiredMinMember)
+	}
+
+	phase, _, _ := unstructured.NestedString(podGroup.Object, "status", "phase")
+	return phase, nil
+}
+
+func (r *AlpineReconciler) createPodGroup(ctx context.Context, alpine *staightv1.Alpine, minMember int32) error {
+	podGroup := &unstructured.Unstructured{}
+	podGroup.SetGroupVersionKind(podGroupGVK)
+	podGroup.SetNamespace(alpine.Namespace)
+	podGroup.SetName(alpine.Name)
+	if err := unstructured.SetNestedField(podGroup.Object, int64(minMember), "spec", "minMember"); err != nil {
+		return err
+	}
+	if err := ctrl.SetControllerReference(alpine, podGroup, r.Scheme); err != nil {
+		return err
+	}
+	return r.Create(ctx, podGroup)
+}
+
+// ensureResourceQuota creates or updates the sibling ResourceQuota named
+// after this Alpine and returns its observed status. ResourceQuota is a
+// namespace-scoped API with no label-selector mechanism for pods, so this
+// quota's Hard/Used apply to the whole namespace, not just this Alpine's
+// pods; Reconcile compensates for that when deciding how many pods it may
+// create (see the comment above the call site). Two Quotas-enabled Alpines
+// sharing a namespace will collide on the namespace-wide accounting.
+func (r *AlpineReconciler) ensureResourceQuota(ctx context.Context, alpine *staightv1.Alpine) (corev1.ResourceQuotaStatus, error) {
+	quotaName := alpine.Name + "-quota"
+
+	var quota corev1.ResourceQuota
+	err := r.Get(ctx, client.ObjectKey{Namespace: alpine.Namespace, Name: quotaName}, &quota)
+	switch {
+	case apierrs.IsNotFound(err):
+		quota = corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: alpine.Namespace,
+				Name:      quotaName,
+				Labels:    map[string]string{alpineNameLabel: alpine.Name},
+			},
+			Spec: *alpine.Spec.Quotas.DeepCopy(),
+		}
+		if err := ctrl.SetControllerReference(alpine, &quota, r.Scheme); err != nil {
+			return corev1.ResourceQuotaStatus{}, err
+		}
+		if err := r.Create(ctx, &quota); err != nil {
+			return corev1.ResourceQuotaStatus{}, err
+		}
+		return quota.Status, nil
+	case err != nil:
+		return corev1.ResourceQuotaStatus{}, err
+	}
+
+	if !reflect.DeepEqual(quota.Spec, alpine.Spec.Quotas) {
+		quota.Spec = *alpine.Spec.Quotas.DeepCopy()
+		if err := r.Update(ctx, &quota); err != nil {
+			return corev1.ResourceQuotaStatus{}, err
+		}
+	}
+	return quota.Status, nil
+}
+
 func (r *AlpineReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	if err := mgr.GetFieldIndexer().IndexField(&corev1.Pod{}, podOwnerKey, func(rawObj runtime.Object) []string {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &corev1.Pod{}, podOwnerKey, func(rawObj client.Object) []string {
 		pod := rawObj.(*corev1.Pod)
 		owner := metav1.GetControllerOf(pod)
 		if owner == nil {
@@ -173,5 +436,6 @@ func (r *AlpineReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&staightv1.Alpine{}).
 		Owns(&corev1.Pod{}).
+		Owns(&corev1.ResourceQuota{}).
 		Complete(r)
 }